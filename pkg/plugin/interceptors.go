@@ -0,0 +1,135 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/status"
+)
+
+// pluginContextKey is the type of the context keys used to thread per-call metadata (the
+// name of the specific plugin being called) through instrumentPluginCall.
+type pluginContextKey string
+
+// pluginNameContextKey carries the registered name of the plugin a client RPC is being
+// made to (e.g. "velero.io/pod"), set via withPluginName before each call.
+const pluginNameContextKey pluginContextKey = "plugin-name"
+
+// withPluginName returns a copy of ctx carrying name, for use by the per-plugin-kind
+// gRPC client methods (RestoreItemActionGRPCClient.Execute, etc.) before invoking an RPC.
+func withPluginName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, pluginNameContextKey, name)
+}
+
+// Prometheus metrics for plugin gRPC calls, labeled by plugin kind (e.g.
+// "RestoreItemAction") and name (e.g. "velero.io/pod"). Only the RestoreItemAction
+// client and server record them; other plugin kinds' calls are not instrumented.
+var (
+	pluginRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "velero_plugin_requests_total",
+		Help: "Total number of requests made to a Velero plugin, by kind, name, method and result code.",
+	}, []string{"plugin_kind", "plugin_name", "method", "code"})
+
+	pluginRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "velero_plugin_request_duration_seconds",
+		Help:    "Latency of requests made to a Velero plugin, by kind, name and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"plugin_kind", "plugin_name", "method"})
+
+	pluginRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "velero_plugin_requests_in_flight",
+		Help: "Number of requests currently in flight to a Velero plugin, by kind and name.",
+	}, []string{"plugin_kind", "plugin_name"})
+)
+
+func init() {
+	prometheus.MustRegister(pluginRequestsTotal, pluginRequestDuration, pluginRequestsInFlight)
+}
+
+// pluginCallInterceptor wraps a single RestoreItemAction RPC identified by kind (the
+// plugin kind, e.g. "RestoreItemAction"), name (e.g. "velero.io/pod") and method (e.g.
+// "Execute"). Implementations call next themselves, which lets them run code both before
+// and after the RPC, same as a grpc.UnaryClientInterceptor.
+type pluginCallInterceptor func(ctx context.Context, kind, name, method string, next func(ctx context.Context) error) error
+
+// pluginInterceptors is the ordered chain instrumentPluginCall runs every RPC through,
+// outermost first. It's a var (rather than a hardcoded pair of calls) so
+// SetPluginInterceptors can add, reorder or drop entries; go-plugin hands GRPCClient and
+// GRPCServer an already-constructed connection/server, too late to attach these as a real
+// grpc.UnaryClientInterceptor/UnaryServerInterceptor chain, so they're run explicitly from
+// each client and server call site instead.
+var pluginInterceptors = []pluginCallInterceptor{
+	prometheusPluginInterceptor,
+	tracingPluginInterceptor,
+}
+
+// SetPluginInterceptors overrides the chain of interceptors instrumentPluginCall runs
+// every RestoreItemAction RPC through, outermost first. Callers that still want the
+// built-in Prometheus metrics and/or OpenTracing spans should include
+// prometheusPluginInterceptor and/or tracingPluginInterceptor in the replacement chain.
+func SetPluginInterceptors(interceptors ...pluginCallInterceptor) {
+	pluginInterceptors = interceptors
+}
+
+// instrumentPluginCall runs call through the configured pluginInterceptors chain,
+// outermost first, for a single RestoreItemAction RPC identified by kind (the plugin
+// kind, e.g. "RestoreItemAction") and method (e.g. "Execute").
+func instrumentPluginCall(ctx context.Context, kind, name, method string, call func(ctx context.Context) error) error {
+	for i := len(pluginInterceptors) - 1; i >= 0; i-- {
+		interceptor := pluginInterceptors[i]
+		next := call
+		call = func(ctx context.Context) error {
+			return interceptor(ctx, kind, name, method, next)
+		}
+	}
+	return call(ctx)
+}
+
+// prometheusPluginInterceptor is the default pluginCallInterceptor recording
+// pluginRequestsTotal, pluginRequestDuration and pluginRequestsInFlight around next.
+func prometheusPluginInterceptor(ctx context.Context, kind, name, method string, next func(ctx context.Context) error) error {
+	inFlight := pluginRequestsInFlight.WithLabelValues(kind, name)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	err := next(ctx)
+
+	pluginRequestDuration.WithLabelValues(kind, name, method).Observe(time.Since(start).Seconds())
+	pluginRequestsTotal.WithLabelValues(kind, name, method, status.Code(err).String()).Inc()
+
+	return err
+}
+
+// tracingPluginInterceptor is the default pluginCallInterceptor starting an OpenTracing
+// span named method around next, tagging it with the error on failure.
+func tracingPluginInterceptor(ctx context.Context, kind, name, method string, next func(ctx context.Context) error) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, method)
+	defer span.Finish()
+
+	err := next(ctx)
+	if err != nil {
+		span.SetTag("error", true)
+		span.LogKV("error.message", err.Error())
+	}
+
+	return err
+}
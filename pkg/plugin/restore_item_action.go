@@ -18,18 +18,83 @@ package plugin
 
 import (
 	"encoding/json"
+	"io"
+	"sync"
+	"time"
 
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	api "github.com/heptio/velero/pkg/apis/velero/v1"
 	proto "github.com/heptio/velero/pkg/plugin/generated"
 	"github.com/heptio/velero/pkg/plugin/velero"
 )
 
+const (
+	// streamChunkThreshold is the combined item/itemFromBackup/restore size, in bytes,
+	// above which Execute switches to the chunked ExecuteStream RPC.
+	streamChunkThreshold = 3 * 1024 * 1024
+
+	// streamFrameSize is the maximum size, in bytes, of a single item-content frame sent
+	// over ExecuteStream.
+	streamFrameSize = 1024 * 1024
+
+	// negotiateRetryInterval is how long a failed capability negotiation is cached before
+	// being retried.
+	negotiateRetryInterval = 10 * time.Minute
+
+	// negotiateTimeout bounds the Negotiate RPC so a hung or unresponsive plugin server
+	// can't block a caller holding on to negotiate's result indefinitely.
+	negotiateTimeout = 10 * time.Second
+)
+
+// maxMessageSize is the client-side gRPC max send/receive message size applied via
+// callOptions, overriding go-plugin's 4MB default. It's a var so SetMaxMessageSize can
+// change it. It has no effect on the server's own receive limit, since GRPCServer
+// receives an already-constructed grpc.Server with no server-option hook left to raise
+// it from here; ExecuteStream's sub-streamFrameSize framing is what actually lets large
+// payloads cross the wire regardless of either side's unary message-size limit.
+var maxMessageSize = 16 * 1024 * 1024
+
+// SetMaxMessageSize overrides the default client-side gRPC max message size used for
+// RestoreItemAction calls.
+func SetMaxMessageSize(n int) {
+	maxMessageSize = n
+}
+
+// callOptions returns the grpc.CallOption set applied to every RestoreItemAction RPC
+// call. It's passed explicitly at each call site because go-plugin hands GRPCClient and
+// GRPCServer an already-constructed connection/server, too late for dial/server options.
+func callOptions() []grpc.CallOption {
+	return []grpc.CallOption{
+		grpc.MaxCallRecvMsgSize(maxMessageSize),
+		grpc.MaxCallSendMsgSize(maxMessageSize),
+	}
+}
+
+// restoreItemActionAPIVersions lists the wire API versions this client understands, most
+// preferred first. The server's Negotiate picks the most preferred one the client also
+// lists, and negotiate records the version it returns.
+var restoreItemActionAPIVersions = []string{"v1", "v1alpha"}
+
+// pluginCapabilities records the result of negotiating with a plugin server: the wire API
+// version both sides agreed on, and the optional RPCs the server advertised support for.
+type pluginCapabilities struct {
+	apiVersion string
+	methods    map[string]bool
+}
+
+// supports reports whether the server advertised support for the named optional RPC.
+func (c *pluginCapabilities) supports(method string) bool {
+	return c != nil && c.methods[method]
+}
+
 // RestoreItemActionPlugin is an implementation of go-plugin's Plugin
 // interface with support for gRPC for the restore/ItemAction
 // interface.
@@ -56,22 +121,82 @@ func (p *RestoreItemActionPlugin) GRPCClient(c *grpc.ClientConn) (interface{}, e
 	return newClientDispenser(p.clientLogger, c, newRestoreItemActionGRPCClient), nil
 }
 
+// restoreItemActionPluginKind labels the kind dimension of the Prometheus metrics this
+// plugin type emits.
+const restoreItemActionPluginKind = "RestoreItemAction"
+
 // RestoreItemActionGRPCClient implements the backup/ItemAction interface and uses a
 // gRPC client to make calls to the plugin server.
 type RestoreItemActionGRPCClient struct {
 	*clientBase
-	grpcClient proto.RestoreItemActionClient
+	grpcClient     proto.RestoreItemActionClient
+	metadataClient proto.PluginMetadataClient
+
+	negotiateMu       sync.Mutex
+	capabilities      *pluginCapabilities
+	negotiateFailedAt time.Time
 }
 
 func newRestoreItemActionGRPCClient(base *clientBase, clientConn *grpc.ClientConn) interface{} {
 	return &RestoreItemActionGRPCClient{
-		clientBase: base,
-		grpcClient: proto.NewRestoreItemActionClient(clientConn),
+		clientBase:     base,
+		grpcClient:     proto.NewRestoreItemActionClient(clientConn),
+		metadataClient: proto.NewPluginMetadataClient(clientConn),
+	}
+}
+
+// negotiate determines the server's capabilities the first time it's called, caching the
+// result for the lifetime of the client so later AppliesTo/Execute calls don't pay for
+// the extra round trip. If the server doesn't implement PluginMetadata at all, the
+// failure is cached for negotiateRetryInterval and nil is returned; callers should treat
+// a nil result as "assume v1alpha behavior".
+func (c *RestoreItemActionGRPCClient) negotiate(ctx context.Context) *pluginCapabilities {
+	c.negotiateMu.Lock()
+	if c.capabilities != nil {
+		defer c.negotiateMu.Unlock()
+		return c.capabilities
+	}
+	if !c.negotiateFailedAt.IsZero() && time.Since(c.negotiateFailedAt) < negotiateRetryInterval {
+		defer c.negotiateMu.Unlock()
+		return nil
+	}
+	c.negotiateMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, negotiateTimeout)
+	defer cancel()
+
+	res, err := c.metadataClient.Negotiate(ctx, &proto.NegotiateRequest{
+		Plugin:            c.plugin,
+		SupportedVersions: restoreItemActionAPIVersions,
+	}, callOptions()...)
+
+	c.negotiateMu.Lock()
+	defer c.negotiateMu.Unlock()
+
+	if err != nil {
+		c.negotiateFailedAt = time.Now()
+		return nil
+	}
+
+	methods := make(map[string]bool, len(res.Methods))
+	for _, m := range res.Methods {
+		methods[m] = true
 	}
+
+	c.capabilities = &pluginCapabilities{apiVersion: res.ApiVersion, methods: methods}
+	return c.capabilities
 }
 
 func (c *RestoreItemActionGRPCClient) AppliesTo() (velero.ResourceSelector, error) {
-	res, err := c.grpcClient.AppliesTo(context.Background(), &proto.AppliesToRequest{Plugin: c.plugin})
+	ctx := withPluginName(context.Background(), c.plugin)
+	c.negotiate(ctx)
+
+	var res *proto.AppliesToResponse
+	err := instrumentPluginCall(ctx, restoreItemActionPluginKind, c.plugin, "AppliesTo", func(ctx context.Context) error {
+		var err error
+		res, err = c.grpcClient.AppliesTo(ctx, &proto.AppliesToRequest{Plugin: c.plugin}, callOptions()...)
+		return err
+	})
 	if err != nil {
 		return velero.ResourceSelector{}, err
 	}
@@ -86,6 +211,43 @@ func (c *RestoreItemActionGRPCClient) AppliesTo() (velero.ResourceSelector, erro
 }
 
 func (c *RestoreItemActionGRPCClient) Execute(input *velero.RestoreItemActionExecuteInput) (*velero.RestoreItemActionExecuteOutput, error) {
+	req, err := newRestoreExecuteRequest(c.plugin, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := withPluginName(context.Background(), c.plugin)
+	caps := c.negotiate(ctx)
+	needsStream := len(req.Item)+len(req.ItemFromBackup)+len(req.Restore) > streamChunkThreshold
+
+	if needsStream && caps.supports("ExecuteStream") {
+		res, err := c.executeStream(ctx, req, input.Progress)
+		if err == nil {
+			return res, nil
+		}
+		if status.Code(err) != codes.Unimplemented {
+			return nil, err
+		}
+		// Server is running an older plugin binary that doesn't implement
+		// ExecuteStream yet; fall back to the unary RPC.
+	}
+
+	var res *proto.RestoreExecuteResponse
+	err = instrumentPluginCall(ctx, restoreItemActionPluginKind, c.plugin, "Execute", func(ctx context.Context) error {
+		var err error
+		res, err = c.grpcClient.Execute(ctx, req, callOptions()...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeExecuteResponse(res)
+}
+
+// newRestoreExecuteRequest marshals input's Item, ItemFromBackup and Restore into the
+// wire format shared by the unary Execute, ExecuteStream and ExecuteBatch RPCs.
+func newRestoreExecuteRequest(plugin string, input *velero.RestoreItemActionExecuteInput) (*proto.RestoreExecuteRequest, error) {
 	itemJSON, err := json.Marshal(input.Item.UnstructuredContent())
 	if err != nil {
 		return nil, err
@@ -101,34 +263,304 @@ func (c *RestoreItemActionGRPCClient) Execute(input *velero.RestoreItemActionExe
 		return nil, err
 	}
 
-	req := &proto.RestoreExecuteRequest{
-		Plugin:         c.plugin,
+	return &proto.RestoreExecuteRequest{
+		Plugin:         plugin,
 		Item:           itemJSON,
 		ItemFromBackup: itemFromBackupJSON,
 		Restore:        restoreJSON,
+	}, nil
+}
+
+// executeBatchSize is the maximum number of items grouped into a single ExecuteBatch RPC.
+var executeBatchSize = 50
+
+// SetExecuteBatchSize overrides the default number of items grouped into a single
+// ExecuteBatch RPC.
+func SetExecuteBatchSize(n int) {
+	executeBatchSize = n
+}
+
+// ExecuteBatch amortizes the per-item JSON marshal and gRPC round trip that dominates
+// restore time when the same action processes tens of thousands of small resources
+// (Secrets, ConfigMaps, Roles, ...). Items are split into runs of at most
+// executeBatchSize that share a GroupVersionKind, each sent as one ExecuteBatch RPC; any
+// run falls back to one Execute call per item if the server doesn't implement
+// ExecuteBatch yet.
+func (c *RestoreItemActionGRPCClient) ExecuteBatch(items []velero.RestoreItemActionExecuteInput) ([]velero.RestoreItemActionExecuteOutput, error) {
+	outputs := make([]velero.RestoreItemActionExecuteOutput, len(items))
+	caps := c.negotiate(withPluginName(context.Background(), c.plugin))
+
+	for _, group := range executeBatchGroups(items, executeBatchSize) {
+		groupItems := make([]velero.RestoreItemActionExecuteInput, len(group))
+		for gi, idx := range group {
+			groupItems[gi] = items[idx]
+		}
+
+		var groupOutputs []velero.RestoreItemActionExecuteOutput
+		var err error
+		if caps.supports("ExecuteBatch") {
+			groupOutputs, err = c.executeBatchGroup(groupItems)
+			if status.Code(err) == codes.Unimplemented {
+				groupOutputs, err = c.executeGroupIndividually(groupItems)
+			}
+		} else {
+			groupOutputs, err = c.executeGroupIndividually(groupItems)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for gi, idx := range group {
+			outputs[idx] = groupOutputs[gi]
+		}
+	}
+
+	return outputs, nil
+}
+
+// executeBatchGroups splits items into the groups ExecuteBatch sends one RPC per:
+// maximal contiguous runs of at most batchSize items that share a GroupVersionKind. It
+// returns the original indices of each group's items so callers can scatter the results
+// back into their original positions.
+func executeBatchGroups(items []velero.RestoreItemActionExecuteInput, batchSize int) [][]int {
+	var groups [][]int
+	var current []int
+	var currentGVK schema.GroupVersionKind
+
+	for i, item := range items {
+		gvk := item.Item.GroupVersionKind()
+		if len(current) > 0 && (gvk != currentGVK || len(current) >= batchSize) {
+			groups = append(groups, current)
+			current = nil
+		}
+		if len(current) == 0 {
+			currentGVK = gvk
+		}
+		current = append(current, i)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+// executeGroupIndividually is the fallback used when the server doesn't implement
+// ExecuteBatch: it issues one Execute RPC per item, in order.
+func (c *RestoreItemActionGRPCClient) executeGroupIndividually(items []velero.RestoreItemActionExecuteInput) ([]velero.RestoreItemActionExecuteOutput, error) {
+	outputs := make([]velero.RestoreItemActionExecuteOutput, len(items))
+
+	for i := range items {
+		out, err := c.Execute(&items[i])
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = *out
+	}
+
+	return outputs, nil
+}
+
+// executeBatchGroup sends a single ExecuteBatch RPC for items, which must already share
+// a GroupVersionKind and number no more than executeBatchSize.
+func (c *RestoreItemActionGRPCClient) executeBatchGroup(items []velero.RestoreItemActionExecuteInput) ([]velero.RestoreItemActionExecuteOutput, error) {
+	reqs := make([]*proto.RestoreExecuteRequest, len(items))
+	for i := range items {
+		req, err := newRestoreExecuteRequest(c.plugin, &items[i])
+		if err != nil {
+			return nil, err
+		}
+		reqs[i] = req
 	}
 
-	res, err := c.grpcClient.Execute(context.Background(), req)
+	ctx := withPluginName(context.Background(), c.plugin)
+	var res *proto.RestoreExecuteBatchResponse
+	err := instrumentPluginCall(ctx, restoreItemActionPluginKind, c.plugin, "ExecuteBatch", func(ctx context.Context) error {
+		var err error
+		res, err = c.grpcClient.ExecuteBatch(ctx, &proto.RestoreExecuteBatchRequest{Plugin: c.plugin, Items: reqs}, callOptions()...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var updatedItem unstructured.Unstructured
-	if err := json.Unmarshal(res.Item, &updatedItem); err != nil {
+	if len(res.Items) != len(items) {
+		return nil, errors.Errorf("ExecuteBatch returned %d results for %d items", len(res.Items), len(items))
+	}
+
+	outputs := make([]velero.RestoreItemActionExecuteOutput, len(items))
+	for i, itemRes := range res.Items {
+		out, err := decodeExecuteResponse(itemRes)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i] = *out
+	}
+
+	return outputs, nil
+}
+
+// executeStream sends req to the server as a header frame followed by one or more
+// item-content frames (chunking Item, ItemFromBackup and Restore to streamFrameSize), and
+// reads back zero or more progress frames followed by a trailer frame containing the
+// result. If progress is non-nil, progress frames are reported through it as they arrive.
+func (c *RestoreItemActionGRPCClient) executeStream(ctx context.Context, req *proto.RestoreExecuteRequest, progress velero.ProgressReporter) (out *velero.RestoreItemActionExecuteOutput, err error) {
+	err = instrumentPluginCall(ctx, restoreItemActionPluginKind, c.plugin, "ExecuteStream", func(ctx context.Context) error {
+		var err error
+		out, err = c.doExecuteStream(ctx, req, progress)
+		return err
+	})
+	return out, err
+}
+
+// doExecuteStream is the uninstrumented body of executeStream.
+func (c *RestoreItemActionGRPCClient) doExecuteStream(ctx context.Context, req *proto.RestoreExecuteRequest, progress velero.ProgressReporter) (*velero.RestoreItemActionExecuteOutput, error) {
+	stream, err := c.grpcClient.ExecuteStream(ctx, callOptions()...)
+	if err != nil {
 		return nil, err
 	}
 
-	var warning error
-	if res.Warning != "" {
-		warning = errors.New(res.Warning)
+	if err := stream.Send(&proto.RestoreExecuteStreamRequest{
+		Header: &proto.RestoreExecuteStreamHeader{Plugin: req.Plugin},
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		kind proto.RestoreExecuteStreamField
+		data []byte
+	}{
+		{proto.RestoreExecuteStreamField_ITEM, req.Item},
+		{proto.RestoreExecuteStreamField_ITEM_FROM_BACKUP, req.ItemFromBackup},
+		{proto.RestoreExecuteStreamField_RESTORE, req.Restore},
+	} {
+		for _, chunk := range chunkBytes(field.data, streamFrameSize) {
+			if err := stream.Send(&proto.RestoreExecuteStreamRequest{
+				Content: &proto.RestoreExecuteStreamContent{Field: field.kind, Chunk: chunk},
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil, errors.New("ExecuteStream closed without a trailer frame")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Progress != nil {
+			if progress != nil {
+				progress.Report(velero.OperationProgress{
+					Completed: res.Progress.Completed,
+					Total:     res.Progress.Total,
+				})
+			}
+			continue
+		}
+
+		return decodeExecuteResponse(res.Trailer)
+	}
+}
+
+// chunkBytes splits data into successive slices of at most size bytes. A nil or empty
+// data still yields a single (possibly empty) chunk, so the receiver always sees at least
+// one frame per field.
+func chunkBytes(data []byte, size int) [][]byte {
+	if len(data) == 0 {
+		return [][]byte{data}
+	}
+
+	var chunks [][]byte
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
+}
+
+// decodeExecuteResponse unmarshals a proto.RestoreExecuteResponse into a
+// velero.RestoreItemActionExecuteOutput, shared by the unary, streaming and batch code
+// paths.
+func decodeExecuteResponse(res *proto.RestoreExecuteResponse) (*velero.RestoreItemActionExecuteOutput, error) {
+	var updatedItem unstructured.Unstructured
+	if err := json.Unmarshal(res.Item, &updatedItem); err != nil {
+		return nil, err
 	}
 
 	return &velero.RestoreItemActionExecuteOutput{
 		UpdatedItem: &updatedItem,
-		Warning:     warning,
+		Warnings:    decodeWarnings(res),
 	}, nil
 }
 
+// decodeWarnings builds the structured warning list for res, preferring the typed
+// Warnings field and falling back to the legacy single-string Warning field when talking
+// to a server running a plugin binary old enough not to populate Warnings yet.
+func decodeWarnings(res *proto.RestoreExecuteResponse) []velero.RestoreWarning {
+	if len(res.Warnings) > 0 {
+		warnings := make([]velero.RestoreWarning, len(res.Warnings))
+		for i, w := range res.Warnings {
+			warnings[i] = velero.RestoreWarning{
+				Code:    decodeWarningCode(w.Code),
+				Message: w.Message,
+				Context: w.Context,
+			}
+		}
+		return warnings
+	}
+
+	if res.Warning != "" {
+		return []velero.RestoreWarning{{
+			Code:    velero.WarningCodeUnknown,
+			Message: res.Warning,
+		}}
+	}
+
+	return nil
+}
+
+func decodeWarningCode(code proto.WarningCode) velero.WarningCode {
+	switch code {
+	case proto.WarningCode_SKIPPED:
+		return velero.WarningCodeSkipped
+	case proto.WarningCode_MUTATED:
+		return velero.WarningCodeMutated
+	case proto.WarningCode_DEPRECATED:
+		return velero.WarningCodeDeprecated
+	case proto.WarningCode_EXTERNAL_DEPENDENCY:
+		return velero.WarningCodeExternalDependency
+	default:
+		return velero.WarningCodeUnknown
+	}
+}
+
+func encodeWarningCode(code velero.WarningCode) proto.WarningCode {
+	switch code {
+	case velero.WarningCodeSkipped:
+		return proto.WarningCode_SKIPPED
+	case velero.WarningCodeMutated:
+		return proto.WarningCode_MUTATED
+	case velero.WarningCodeDeprecated:
+		return proto.WarningCode_DEPRECATED
+	case velero.WarningCodeExternalDependency:
+		return proto.WarningCode_EXTERNAL_DEPENDENCY
+	default:
+		return proto.WarningCode_UNKNOWN
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////
 // server code
 //////////////////////////////////////////////////////////////////////////////
@@ -136,9 +568,36 @@ func (c *RestoreItemActionGRPCClient) Execute(input *velero.RestoreItemActionExe
 // GRPCServer registers a RestoreItemAction gRPC server.
 func (p *RestoreItemActionPlugin) GRPCServer(s *grpc.Server) error {
 	proto.RegisterRestoreItemActionServer(s, &RestoreItemActionGRPCServer{mux: p.serverMux})
+	proto.RegisterPluginMetadataServer(s, &restoreItemActionMetadataServer{})
 	return nil
 }
 
+// restoreItemActionMetadataServer implements the PluginMetadata service for
+// RestoreItemAction, letting clients discover its API version and optional RPCs.
+type restoreItemActionMetadataServer struct{}
+
+func (s *restoreItemActionMetadataServer) Negotiate(ctx context.Context, req *proto.NegotiateRequest) (*proto.NegotiateResponse, error) {
+	requested := make(map[string]bool, len(req.SupportedVersions))
+	for _, v := range req.SupportedVersions {
+		requested[v] = true
+	}
+
+	// restoreItemActionAPIVersions is ordered most-preferred first; pick the first one the
+	// client also supports, falling back to the oldest version we speak.
+	apiVersion := restoreItemActionAPIVersions[len(restoreItemActionAPIVersions)-1]
+	for _, v := range restoreItemActionAPIVersions {
+		if requested[v] {
+			apiVersion = v
+			break
+		}
+	}
+
+	return &proto.NegotiateResponse{
+		ApiVersion: apiVersion,
+		Methods:    []string{"AppliesTo", "Execute", "ExecuteStream", "ExecuteBatch"},
+	}, nil
+}
+
 // RestoreItemActionGRPCServer implements the proto-generated RestoreItemActionServer interface, and accepts
 // gRPC calls and forwards them to an implementation of the pluggable interface.
 type RestoreItemActionGRPCServer struct {
@@ -166,37 +625,208 @@ func (s *RestoreItemActionGRPCServer) AppliesTo(ctx context.Context, req *proto.
 		}
 	}()
 
+	err = instrumentPluginCall(ctx, restoreItemActionPluginKind, req.Plugin, "AppliesTo", func(ctx context.Context) error {
+		impl, err := s.getImpl(req.Plugin)
+		if err != nil {
+			return err
+		}
+
+		appliesTo, err := impl.AppliesTo()
+		if err != nil {
+			return err
+		}
+
+		response = &proto.AppliesToResponse{
+			IncludedNamespaces: appliesTo.IncludedNamespaces,
+			ExcludedNamespaces: appliesTo.ExcludedNamespaces,
+			IncludedResources:  appliesTo.IncludedResources,
+			ExcludedResources:  appliesTo.ExcludedResources,
+			Selector:           appliesTo.LabelSelector,
+		}
+		return nil
+	})
+
+	return response, err
+}
+
+func (s *RestoreItemActionGRPCServer) Execute(ctx context.Context, req *proto.RestoreExecuteRequest) (response *proto.RestoreExecuteResponse, err error) {
+	defer func() {
+		if recoveredErr := handlePanic(recover()); recoveredErr != nil {
+			err = recoveredErr
+		}
+	}()
+
+	err = instrumentPluginCall(ctx, restoreItemActionPluginKind, req.Plugin, "Execute", func(ctx context.Context) error {
+		var err error
+		response, err = s.execute(req, nil)
+		return err
+	})
+
+	return response, err
+}
+
+// ExecuteBatch is the batched counterpart to Execute. If the plugin implements
+// velero.BatchRestoreItemAction, its ExecuteBatch method is invoked directly with every
+// decoded item; otherwise the default implementation below loops over Execute once per
+// item, so older plugins that only implement velero.RestoreItemAction keep working
+// unchanged.
+func (s *RestoreItemActionGRPCServer) ExecuteBatch(ctx context.Context, req *proto.RestoreExecuteBatchRequest) (response *proto.RestoreExecuteBatchResponse, err error) {
+	defer func() {
+		if recoveredErr := handlePanic(recover()); recoveredErr != nil {
+			err = recoveredErr
+		}
+	}()
+
+	err = instrumentPluginCall(ctx, restoreItemActionPluginKind, req.Plugin, "ExecuteBatch", func(ctx context.Context) error {
+		var err error
+		response, err = s.executeBatch(req)
+		return err
+	})
+
+	return response, err
+}
+
+func (s *RestoreItemActionGRPCServer) executeBatch(req *proto.RestoreExecuteBatchRequest) (*proto.RestoreExecuteBatchResponse, error) {
 	impl, err := s.getImpl(req.Plugin)
 	if err != nil {
 		return nil, err
 	}
 
-	appliesTo, err := impl.AppliesTo()
+	batchImpl, ok := impl.(velero.BatchRestoreItemAction)
+	if !ok {
+		results := make([]*proto.RestoreExecuteResponse, len(req.Items))
+		for i, itemReq := range req.Items {
+			res, err := s.execute(itemReq, nil)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = res
+		}
+
+		return &proto.RestoreExecuteBatchResponse{Items: results}, nil
+	}
+
+	inputs := make([]velero.RestoreItemActionExecuteInput, len(req.Items))
+	for i, itemReq := range req.Items {
+		input, err := decodeRestoreExecuteRequest(itemReq)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = *input
+	}
+
+	outputs, err := batchImpl.ExecuteBatch(inputs)
 	if err != nil {
 		return nil, err
 	}
+	if len(outputs) != len(inputs) {
+		return nil, errors.Errorf("plugin %s ExecuteBatch returned %d outputs for %d inputs", req.Plugin, len(outputs), len(inputs))
+	}
 
-	return &proto.AppliesToResponse{
-		IncludedNamespaces: appliesTo.IncludedNamespaces,
-		ExcludedNamespaces: appliesTo.ExcludedNamespaces,
-		IncludedResources:  appliesTo.IncludedResources,
-		ExcludedResources:  appliesTo.ExcludedResources,
-		Selector:           appliesTo.LabelSelector,
-	}, nil
+	results := make([]*proto.RestoreExecuteResponse, len(outputs))
+	for i := range outputs {
+		res, err := encodeExecuteOutput(&outputs[i])
+		if err != nil {
+			return nil, err
+		}
+		results[i] = res
+	}
+
+	return &proto.RestoreExecuteBatchResponse{Items: results}, nil
 }
 
-func (s *RestoreItemActionGRPCServer) Execute(ctx context.Context, req *proto.RestoreExecuteRequest) (response *proto.RestoreExecuteResponse, err error) {
+// ExecuteStream is the streaming counterpart to Execute. The client sends a header frame
+// identifying the plugin followed by one or more item-content frames, which are
+// reassembled here before invoking the plugin. The plugin's Execute input carries a
+// Progress reporter, and each progress update it reports is streamed back to the client
+// as a progress frame; the result is always sent last, as a trailer frame.
+func (s *RestoreItemActionGRPCServer) ExecuteStream(stream proto.RestoreItemAction_ExecuteStreamServer) (err error) {
 	defer func() {
 		if recoveredErr := handlePanic(recover()); recoveredErr != nil {
 			err = recoveredErr
 		}
 	}()
 
+	header, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if header.Header == nil {
+		return errors.New("ExecuteStream: first frame must be a header frame")
+	}
+	pluginName := header.Header.Plugin
+
+	return instrumentPluginCall(stream.Context(), restoreItemActionPluginKind, pluginName, "ExecuteStream", func(ctx context.Context) error {
+		content := map[proto.RestoreExecuteStreamField][]byte{}
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if req.Content == nil {
+				return errors.New("ExecuteStream: expected a content frame")
+			}
+			content[req.Content.Field] = append(content[req.Content.Field], req.Content.Chunk...)
+		}
+
+		req := &proto.RestoreExecuteRequest{
+			Plugin:         pluginName,
+			Item:           content[proto.RestoreExecuteStreamField_ITEM],
+			ItemFromBackup: content[proto.RestoreExecuteStreamField_ITEM_FROM_BACKUP],
+			Restore:        content[proto.RestoreExecuteStreamField_RESTORE],
+		}
+
+		res, err := s.execute(req, &streamProgressReporter{stream: stream})
+		if err != nil {
+			return err
+		}
+
+		return stream.Send(&proto.RestoreExecuteStreamResponse{Trailer: res})
+	})
+}
+
+// streamProgressReporter implements velero.ProgressReporter by forwarding each reported
+// progress update to the client as a progress frame on an in-flight ExecuteStream call.
+type streamProgressReporter struct {
+	stream proto.RestoreItemAction_ExecuteStreamServer
+}
+
+func (r *streamProgressReporter) Report(p velero.OperationProgress) {
+	_ = r.stream.Send(&proto.RestoreExecuteStreamResponse{
+		Progress: &proto.RestoreExecuteStreamProgress{Completed: p.Completed, Total: p.Total},
+	})
+}
+
+// execute decodes req, invokes the named plugin's Execute method, and encodes the
+// result. progress, if non-nil, is attached to the execute input so the plugin can
+// report progress on long-running executions; it is used only by ExecuteStream, since
+// the unary Execute and ExecuteBatch RPCs have no channel to carry it.
+func (s *RestoreItemActionGRPCServer) execute(req *proto.RestoreExecuteRequest, progress velero.ProgressReporter) (*proto.RestoreExecuteResponse, error) {
 	impl, err := s.getImpl(req.Plugin)
 	if err != nil {
 		return nil, err
 	}
 
+	input, err := decodeRestoreExecuteRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	input.Progress = progress
+
+	executeOutput, err := impl.Execute(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeExecuteOutput(executeOutput)
+}
+
+// decodeRestoreExecuteRequest unmarshals req's Item, ItemFromBackup and Restore, shared
+// by the unary Execute, ExecuteStream and ExecuteBatch server handlers.
+func decodeRestoreExecuteRequest(req *proto.RestoreExecuteRequest) (*velero.RestoreItemActionExecuteInput, error) {
 	var (
 		item           unstructured.Unstructured
 		itemFromBackup unstructured.Unstructured
@@ -215,27 +845,37 @@ func (s *RestoreItemActionGRPCServer) Execute(ctx context.Context, req *proto.Re
 		return nil, err
 	}
 
-	executeOutput, err := impl.Execute(&velero.RestoreItemActionExecuteInput{
+	return &velero.RestoreItemActionExecuteInput{
 		Item:           &item,
 		ItemFromBackup: &itemFromBackup,
 		Restore:        &restoreObj,
-	})
-	if err != nil {
-		return nil, err
-	}
+	}, nil
+}
 
-	updatedItem, err := json.Marshal(executeOutput.UpdatedItem)
+// encodeExecuteOutput marshals a velero.RestoreItemActionExecuteOutput into the wire
+// format shared by the unary Execute, ExecuteStream and ExecuteBatch server handlers.
+func encodeExecuteOutput(output *velero.RestoreItemActionExecuteOutput) (*proto.RestoreExecuteResponse, error) {
+	updatedItem, err := json.Marshal(output.UpdatedItem)
 	if err != nil {
 		return nil, err
 	}
 
-	var warnMessage string
-	if executeOutput.Warning != nil {
-		warnMessage = executeOutput.Warning.Error()
+	res := &proto.RestoreExecuteResponse{Item: updatedItem}
+
+	if len(output.Warnings) > 0 {
+		res.Warnings = make([]*proto.Warning, len(output.Warnings))
+		for i, w := range output.Warnings {
+			res.Warnings[i] = &proto.Warning{
+				Code:    encodeWarningCode(w.Code),
+				Message: w.Message,
+				Context: w.Context,
+			}
+		}
+
+		// Also populate the legacy single-string field, so an older client that
+		// doesn't know about Warnings yet still surfaces something.
+		res.Warning = output.Warnings[0].Message
 	}
 
-	return &proto.RestoreExecuteResponse{
-		Item:    updatedItem,
-		Warning: warnMessage,
-	}, nil
+	return res, nil
 }
@@ -0,0 +1,251 @@
+/*
+Copyright 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	proto "github.com/heptio/velero/pkg/plugin/generated"
+	"github.com/heptio/velero/pkg/plugin/velero"
+)
+
+// fakeMetadataClient is a proto.PluginMetadataClient that counts Negotiate calls and
+// returns a fixed response/error, for exercising RestoreItemActionGRPCClient.negotiate's
+// caching behavior without a real gRPC connection.
+type fakeMetadataClient struct {
+	calls int
+	resp  *proto.NegotiateResponse
+	err   error
+}
+
+func (f *fakeMetadataClient) Negotiate(ctx context.Context, in *proto.NegotiateRequest, opts ...grpc.CallOption) (*proto.NegotiateResponse, error) {
+	f.calls++
+	return f.resp, f.err
+}
+
+func TestNegotiateCachesSuccess(t *testing.T) {
+	fake := &fakeMetadataClient{resp: &proto.NegotiateResponse{ApiVersion: "v1", Methods: []string{"ExecuteStream"}}}
+	c := &RestoreItemActionGRPCClient{metadataClient: fake}
+
+	caps := c.negotiate(context.Background())
+	if caps == nil || caps.apiVersion != "v1" || !caps.supports("ExecuteStream") {
+		t.Fatalf("negotiate() = %+v, want apiVersion v1 supporting ExecuteStream", caps)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("after first negotiate(), calls = %d, want 1", fake.calls)
+	}
+
+	if caps2 := c.negotiate(context.Background()); caps2 != caps {
+		t.Fatalf("second negotiate() = %+v, want cached %+v", caps2, caps)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("after second negotiate(), calls = %d, want 1 (cached)", fake.calls)
+	}
+}
+
+func TestNegotiateCachesFailure(t *testing.T) {
+	fake := &fakeMetadataClient{err: context.DeadlineExceeded}
+	c := &RestoreItemActionGRPCClient{metadataClient: fake}
+
+	if caps := c.negotiate(context.Background()); caps != nil {
+		t.Fatalf("negotiate() = %+v, want nil on failure", caps)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("after first negotiate(), calls = %d, want 1", fake.calls)
+	}
+
+	if caps := c.negotiate(context.Background()); caps != nil {
+		t.Fatalf("second negotiate() = %+v, want nil (failure still cached)", caps)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("after second negotiate() within negotiateRetryInterval, calls = %d, want 1 (cached)", fake.calls)
+	}
+
+	c.negotiateFailedAt = time.Now().Add(-negotiateRetryInterval - time.Second)
+	c.negotiate(context.Background())
+	if fake.calls != 2 {
+		t.Fatalf("after negotiateRetryInterval elapsed, calls = %d, want 2 (retried)", fake.calls)
+	}
+}
+
+func gvkItem(apiVersion, kind string) velero.RestoreItemActionExecuteInput {
+	item := &unstructured.Unstructured{}
+	item.SetAPIVersion(apiVersion)
+	item.SetKind(kind)
+	return velero.RestoreItemActionExecuteInput{Item: item}
+}
+
+func TestExecuteBatchGroups(t *testing.T) {
+	items := []velero.RestoreItemActionExecuteInput{
+		gvkItem("v1", "Secret"),
+		gvkItem("v1", "Secret"),
+		gvkItem("v1", "ConfigMap"),
+		gvkItem("v1", "ConfigMap"),
+		gvkItem("v1", "ConfigMap"),
+	}
+
+	t.Run("groups split on GroupVersionKind changes", func(t *testing.T) {
+		groups := executeBatchGroups(items, 10)
+		want := [][]int{{0, 1}, {2, 3, 4}}
+		if len(groups) != len(want) {
+			t.Fatalf("executeBatchGroups() = %v, want %v", groups, want)
+		}
+		for i := range groups {
+			if !intSlicesEqual(groups[i], want[i]) {
+				t.Errorf("group %d = %v, want %v", i, groups[i], want[i])
+			}
+		}
+	})
+
+	t.Run("groups also split once batchSize is reached", func(t *testing.T) {
+		groups := executeBatchGroups(items, 2)
+		want := [][]int{{0, 1}, {2, 3}, {4}}
+		if len(groups) != len(want) {
+			t.Fatalf("executeBatchGroups() = %v, want %v", groups, want)
+		}
+		for i := range groups {
+			if !intSlicesEqual(groups[i], want[i]) {
+				t.Errorf("group %d = %v, want %v", i, groups[i], want[i])
+			}
+		}
+	})
+
+	t.Run("empty input yields no groups", func(t *testing.T) {
+		if groups := executeBatchGroups(nil, 10); len(groups) != 0 {
+			t.Errorf("executeBatchGroups(nil, 10) = %v, want no groups", groups)
+		}
+	})
+}
+
+func intSlicesEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEncodeDecodeWarningCodeRoundTrip(t *testing.T) {
+	codes := []velero.WarningCode{
+		velero.WarningCodeUnknown,
+		velero.WarningCodeSkipped,
+		velero.WarningCodeMutated,
+		velero.WarningCodeDeprecated,
+		velero.WarningCodeExternalDependency,
+	}
+
+	for _, code := range codes {
+		if got := decodeWarningCode(encodeWarningCode(code)); got != code {
+			t.Errorf("decodeWarningCode(encodeWarningCode(%v)) = %v, want %v", code, got, code)
+		}
+	}
+}
+
+func TestDecodeWarningsPrefersTypedWarnings(t *testing.T) {
+	res := &proto.RestoreExecuteResponse{
+		Warning: "legacy message, should be ignored",
+		Warnings: []*proto.Warning{
+			{Code: proto.WarningCode_MUTATED, Message: "field X was dropped", Context: "spec.x"},
+		},
+	}
+
+	got := decodeWarnings(res)
+	want := []velero.RestoreWarning{{Code: velero.WarningCodeMutated, Message: "field X was dropped", Context: "spec.x"}}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("decodeWarnings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeWarningsFallsBackToLegacyField(t *testing.T) {
+	res := &proto.RestoreExecuteResponse{Warning: "old server, only a message"}
+
+	got := decodeWarnings(res)
+	want := []velero.RestoreWarning{{Code: velero.WarningCodeUnknown, Message: "old server, only a message"}}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("decodeWarnings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeWarningsNoWarnings(t *testing.T) {
+	if got := decodeWarnings(&proto.RestoreExecuteResponse{}); got != nil {
+		t.Fatalf("decodeWarnings() = %+v, want nil", got)
+	}
+}
+
+func TestChunkBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		size int
+		want [][]byte
+	}{
+		{
+			name: "nil data yields a single empty chunk",
+			data: nil,
+			size: 4,
+			want: [][]byte{nil},
+		},
+		{
+			name: "empty data yields a single empty chunk",
+			data: []byte{},
+			size: 4,
+			want: [][]byte{{}},
+		},
+		{
+			name: "data shorter than size yields a single chunk",
+			data: []byte("abc"),
+			size: 4,
+			want: [][]byte{[]byte("abc")},
+		},
+		{
+			name: "data exactly divisible by size",
+			data: []byte("abcdefgh"),
+			size: 4,
+			want: [][]byte{[]byte("abcd"), []byte("efgh")},
+		},
+		{
+			name: "data not evenly divisible by size leaves a short final chunk",
+			data: []byte("abcdefghi"),
+			size: 4,
+			want: [][]byte{[]byte("abcd"), []byte("efgh"), []byte("i")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := chunkBytes(tc.data, tc.size)
+			if len(got) != len(tc.want) {
+				t.Fatalf("chunkBytes(%q, %d) = %d chunks, want %d", tc.data, tc.size, len(got), len(tc.want))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tc.want[i]) {
+					t.Errorf("chunkBytes(%q, %d) chunk %d = %q, want %q", tc.data, tc.size, i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2017, 2019 the Velero contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package velero defines the interfaces a RestoreItemAction plugin implements, shared by
+// both the gRPC client/server plumbing in pkg/plugin and the plugin implementations
+// themselves.
+package velero
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	api "github.com/heptio/velero/pkg/apis/velero/v1"
+)
+
+// ResourceSelector is a collection of included/excluded namespaces and resources used to
+// filter which resources a RestoreItemAction applies to.
+type ResourceSelector struct {
+	IncludedNamespaces []string
+	ExcludedNamespaces []string
+	IncludedResources  []string
+	ExcludedResources  []string
+	LabelSelector      string
+}
+
+// RestoreItemActionExecuteInput is the input for a RestoreItemAction's Execute method.
+type RestoreItemActionExecuteInput struct {
+	// Item is the item being restored, as recorded in the backup.
+	Item *unstructured.Unstructured
+	// ItemFromBackup is the same item, included for clarity alongside Item.
+	ItemFromBackup *unstructured.Unstructured
+	// Restore is the restore this item is being restored as part of.
+	Restore *api.Restore
+	// Progress, if non-nil, lets Execute report incremental progress on a long-running
+	// restore; only populated when the call was made over ExecuteStream.
+	Progress ProgressReporter
+}
+
+// RestoreItemActionExecuteOutput is the output from a RestoreItemAction's Execute method.
+type RestoreItemActionExecuteOutput struct {
+	// UpdatedItem is the item being restored, mutated as necessary by Execute.
+	UpdatedItem *unstructured.Unstructured
+	// Warnings lists any non-fatal issues Execute encountered while processing the item.
+	Warnings []RestoreWarning
+}
+
+// WarningCode categorizes a RestoreWarning so callers can act on it programmatically
+// instead of pattern-matching its Message.
+type WarningCode int
+
+const (
+	// WarningCodeUnknown is the zero value, used for warnings that predate WarningCode
+	// (decoded from the legacy single-string Warning field) or don't fit another code.
+	WarningCodeUnknown WarningCode = iota
+	// WarningCodeSkipped means the item was left out of the restore entirely.
+	WarningCodeSkipped
+	// WarningCodeMutated means the item was restored but Execute changed it from what
+	// was recorded in the backup.
+	WarningCodeMutated
+	// WarningCodeDeprecated means the item uses an API that's deprecated in the cluster
+	// being restored into.
+	WarningCodeDeprecated
+	// WarningCodeExternalDependency means the item depends on state outside the backup
+	// (an external secret store, a cloud resource, ...) that Execute couldn't verify.
+	WarningCodeExternalDependency
+)
+
+// RestoreWarning is a single non-fatal issue a RestoreItemAction encountered while
+// processing an item.
+type RestoreWarning struct {
+	// Code categorizes the warning.
+	Code WarningCode
+	// Message is a human-readable description of the warning.
+	Message string
+	// Context, if non-empty, identifies the part of the item the warning applies to
+	// (e.g. a field path).
+	Context string
+}
+
+// ProgressReporter lets a RestoreItemAction report incremental progress on a long-running
+// Execute call.
+type ProgressReporter interface {
+	Report(OperationProgress)
+}
+
+// OperationProgress describes how far a long-running Execute call has gotten.
+type OperationProgress struct {
+	Completed int64
+	Total     int64
+}
+
+// RestoreItemAction is a pluggable interface for custom logic executed during a restore.
+type RestoreItemAction interface {
+	// AppliesTo returns the resources this action should be invoked for.
+	AppliesTo() (ResourceSelector, error)
+
+	// Execute allows the ItemAction to perform arbitrary logic with the item being
+	// restored, including mutating the item itself prior to restore.
+	Execute(input *RestoreItemActionExecuteInput) (*RestoreItemActionExecuteOutput, error)
+}
+
+// BatchRestoreItemAction is an optional extension of RestoreItemAction for plugins that
+// can process a batch of same-GroupVersionKind items more efficiently than one Execute
+// call per item. A plugin that doesn't implement it is still called through Execute once
+// per item.
+type BatchRestoreItemAction interface {
+	RestoreItemAction
+
+	// ExecuteBatch behaves like calling Execute once per entry of items, but lets the
+	// plugin amortize work (a single upstream API call, a shared cache lookup, ...)
+	// across the whole batch. The returned slice must have the same length as items, in
+	// the same order.
+	ExecuteBatch(items []RestoreItemActionExecuteInput) ([]RestoreItemActionExecuteOutput, error)
+}